@@ -0,0 +1,384 @@
+package excel_exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// backend hides the format-specific details of writing a sheet so that
+// Exporter.Export can drive xlsx, CSV, TSV and JSONL output through the same
+// SheetData pipeline.
+type backend interface {
+	// newSheet starts a new sheet (or file, for the flat-file formats) with the given name.
+	newSheet(name string) error
+	// applyLayout applies the parts of a SheetLayout that must be set before
+	// any rows are written (column widths, frozen panes).
+	applyLayout(layout SheetLayout) error
+	// writeRow writes a single row at the given 1-based row number.
+	writeRow(rowID int, row Row) error
+	// finalizeLayout applies the parts of a SheetLayout that must be set
+	// after rows are written (auto-filter, tables).
+	finalizeLayout(layout SheetLayout) error
+	// finalize flushes any buffered state for the sheet just written.
+	finalize() error
+	// save writes the finished output to w.
+	save(w io.Writer) error
+	// bufferedBytes approximates how many bytes of output are currently held
+	// in memory, for ProgressFunc. Backends that can't cheaply tell report 0.
+	bufferedBytes() int64
+}
+
+// newBackend selects the backend matching e.Format.
+func (e *Exporter) newBackend() backend {
+	switch e.Format {
+	case FormatCSV:
+		return newFlatFileBackend(FormatCSV)
+	case FormatTSV:
+		return newFlatFileBackend(FormatTSV)
+	case FormatJSONL:
+		return newFlatFileBackend(FormatJSONL)
+	default:
+		return &xlsxBackend{exporter: e}
+	}
+}
+
+// xlsxBackend is the original excelize-backed implementation, writing either
+// straight into memory or through excelize's StreamWriter.
+type xlsxBackend struct {
+	exporter      *Exporter
+	sheetsCreated int
+}
+
+func (b *xlsxBackend) newSheet(name string) error {
+	e := b.exporter
+
+	// cell merge and style will be lost if no flush
+	if e.UseStreamWriter && e.StreamWriter != nil {
+		if err := e.StreamWriter.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if _, err := e.File.NewSheet(name); err != nil {
+		return fmt.Errorf("failed to create a new sheet: %w", err)
+	}
+	b.sheetsCreated++
+
+	// excelize.NewFile starts every workbook with one sheet named "Sheet1".
+	// Only the very first sheet we create is ever a candidate to replace it:
+	// if that first sheet is itself named "Sheet1", File.NewSheet returns the
+	// existing index and SheetCount stays at 1, so the delete below correctly
+	// no-ops. Checking sheetsCreated == 1 (rather than "have we deleted it
+	// yet") keeps later sheets from ever matching this case, so a later sheet
+	// legitimately named "Sheet1" can't be mistaken for the placeholder.
+	if b.sheetsCreated == 1 && e.File.SheetCount > 1 {
+		if err := e.File.DeleteSheet("Sheet1"); err != nil {
+			return fmt.Errorf("failed to delete default sheet: %w", err)
+		}
+	}
+
+	if e.UseStreamWriter {
+		sw, err := e.File.NewStreamWriter(name)
+		if err != nil {
+			return err
+		}
+		e.StreamWriter = sw
+	}
+
+	return nil
+}
+
+func (b *xlsxBackend) applyLayout(layout SheetLayout) error {
+	e := b.exporter
+
+	for col, width := range layout.ColumnWidths {
+		if err := e.setColWidth(col, width); err != nil {
+			return err
+		}
+	}
+
+	if layout.FreezePanes != nil {
+		panes, err := layout.FreezePanes.toExcelize()
+		if err != nil {
+			return err
+		}
+		if err := e.setPanes(panes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Exporter) setColWidth(col string, width float64) error {
+	if e.UseStreamWriter {
+		idx, err := excelize.ColumnNameToNumber(col)
+		if err != nil {
+			return err
+		}
+		return e.StreamWriter.SetColWidth(idx, idx, width)
+	}
+	return e.File.SetColWidth(e.CurrentSheet, col, col, width)
+}
+
+func (e *Exporter) setPanes(panes *excelize.Panes) error {
+	if e.UseStreamWriter {
+		return e.StreamWriter.SetPanes(panes)
+	}
+	return e.File.SetPanes(e.CurrentSheet, panes)
+}
+
+func (b *xlsxBackend) finalizeLayout(layout SheetLayout) error {
+	e := b.exporter
+
+	// excelize.StreamWriter only tracks one table per sheet; each AddTable
+	// call replaces the previous one rather than appending, so anything past
+	// the first would be silently dropped from stream-mode output.
+	if e.UseStreamWriter && len(layout.Tables) > 1 {
+		return fmt.Errorf("excel_exporter: stream writer supports only one table per sheet %q, got %d", e.CurrentSheet, len(layout.Tables))
+	}
+
+	for _, table := range layout.Tables {
+		if err := e.addTable(table.toExcelize()); err != nil {
+			return err
+		}
+	}
+
+	if layout.AutoFilter != "" {
+		if err := e.File.AutoFilter(e.CurrentSheet, layout.AutoFilter, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Exporter) addTable(table *excelize.Table) error {
+	if e.UseStreamWriter {
+		return e.StreamWriter.AddTable(table)
+	}
+	return e.File.AddTable(e.CurrentSheet, table)
+}
+
+func (b *xlsxBackend) writeRow(rowID int, row Row) error {
+	if b.exporter.UseStreamWriter {
+		return b.exporter.writeRowStream(rowID, row)
+	}
+	return b.exporter.writeRowMemory(rowID, row)
+}
+
+func (b *xlsxBackend) finalize() error {
+	if b.exporter.UseStreamWriter && b.exporter.StreamWriter != nil {
+		return b.exporter.StreamWriter.Flush()
+	}
+	return nil
+}
+
+func (b *xlsxBackend) save(w io.Writer) error {
+	return b.exporter.File.Write(w)
+}
+
+// bufferedBytes is unsupported: excelize doesn't expose the in-progress size
+// of a workbook or StreamWriter, so xlsx exports report no progress size.
+func (b *xlsxBackend) bufferedBytes() int64 {
+	return 0
+}
+
+func (e *Exporter) writeRowStream(rowID int, row Row) error {
+	rowCells := make([]interface{}, len(row.Cells))
+	for j, cell := range row.Cells {
+		rowCells[j] = cell
+	}
+
+	cell, _ := excelize.CoordinatesToCellName(1, rowID)
+	if err := e.StreamWriter.SetRow(cell, rowCells, row.RowOpts...); err != nil {
+		return err
+	}
+
+	for _, mergeCell := range row.MergeCells {
+		if err := e.StreamWriter.MergeCell(mergeCell.TopLeftCell, mergeCell.BottomRightCell); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *Exporter) writeRowMemory(rowID int, row Row) error {
+	for j, cell := range row.Cells {
+		cellName, _ := excelize.CoordinatesToCellName(j+1, rowID)
+		if err := e.File.SetCellValue(e.CurrentSheet, cellName, cell.Value); err != nil {
+			return err
+		}
+
+		if cell.StyleID > 0 {
+			if err := e.File.SetCellStyle(e.CurrentSheet, cellName, cellName, cell.StyleID); err != nil {
+				return err
+			}
+		}
+
+		if cell.Formula != "" {
+			if err := e.File.SetCellFormula(e.CurrentSheet, cellName, cell.Formula); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, mergeCell := range row.MergeCells {
+		if err := e.File.MergeCell(e.CurrentSheet, mergeCell.TopLeftCell, mergeCell.BottomRightCell); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flatFileSheet holds the buffered output for one sheet of a flat-file export.
+type flatFileSheet struct {
+	name string
+	buf  *bytes.Buffer
+	csv  *csv.Writer // nil for FormatJSONL
+}
+
+// flatFileBackend implements backend for the plain-text row formats (CSV,
+// TSV, JSONL). Each sheet becomes one file, including the overflow sheets
+// exportHelper starts past SheetMaxRows, so one SheetData can yield several
+// files. The files are zipped together when there is more than one sheet and
+// written out directly otherwise. Cell.Formula is
+// written as the value's string form, styles are ignored and MergeCells are
+// flattened.
+type flatFileBackend struct {
+	format Format
+	sheets []*flatFileSheet
+	cur    *flatFileSheet
+}
+
+func newFlatFileBackend(format Format) *flatFileBackend {
+	return &flatFileBackend{format: format}
+}
+
+func (b *flatFileBackend) newSheet(name string) error {
+	if b.cur != nil {
+		if err := b.finalize(); err != nil {
+			return err
+		}
+	}
+
+	sheet := &flatFileSheet{name: name, buf: &bytes.Buffer{}}
+	if b.format == FormatCSV || b.format == FormatTSV {
+		w := csv.NewWriter(sheet.buf)
+		if b.format == FormatTSV {
+			w.Comma = '\t'
+		}
+		sheet.csv = w
+	}
+
+	b.sheets = append(b.sheets, sheet)
+	b.cur = sheet
+
+	return nil
+}
+
+// applyLayout is a no-op: column widths and frozen panes are xlsx-only
+// concepts. Header is handled generically in Exporter.startSheet.
+func (b *flatFileBackend) applyLayout(layout SheetLayout) error {
+	return nil
+}
+
+// finalizeLayout is a no-op: auto-filters and tables are xlsx-only concepts.
+func (b *flatFileBackend) finalizeLayout(layout SheetLayout) error {
+	return nil
+}
+
+func (b *flatFileBackend) writeRow(rowID int, row Row) error {
+	if b.format == FormatJSONL {
+		return b.writeRowJSONL(row)
+	}
+	return b.writeRowDelimited(row)
+}
+
+func (b *flatFileBackend) writeRowDelimited(row Row) error {
+	record := make([]string, len(row.Cells))
+	for i, cell := range row.Cells {
+		if cell.Formula != "" {
+			record[i] = cell.Formula
+		} else {
+			record[i] = fmt.Sprint(cell.Value)
+		}
+	}
+	return b.cur.csv.Write(record)
+}
+
+func (b *flatFileBackend) writeRowJSONL(row Row) error {
+	values := make([]interface{}, len(row.Cells))
+	for i, cell := range row.Cells {
+		if cell.Formula != "" {
+			values[i] = cell.Formula
+		} else {
+			values[i] = cell.Value
+		}
+	}
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	encoded = append(encoded, '\n')
+
+	_, err = b.cur.buf.Write(encoded)
+	return err
+}
+
+func (b *flatFileBackend) finalize() error {
+	if b.cur == nil || b.cur.csv == nil {
+		return nil
+	}
+
+	b.cur.csv.Flush()
+	return b.cur.csv.Error()
+}
+
+func (b *flatFileBackend) extension() string {
+	switch b.format {
+	case FormatTSV:
+		return ".tsv"
+	case FormatJSONL:
+		return ".jsonl"
+	default:
+		return ".csv"
+	}
+}
+
+func (b *flatFileBackend) bufferedBytes() int64 {
+	var total int64
+	for _, sheet := range b.sheets {
+		total += int64(sheet.buf.Len())
+	}
+	return total
+}
+
+func (b *flatFileBackend) save(w io.Writer) error {
+	if len(b.sheets) == 1 {
+		_, err := w.Write(b.sheets[0].buf.Bytes())
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	for _, sheet := range b.sheets {
+		f, err := zw.Create(sheet.name + b.extension())
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(sheet.buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
@@ -0,0 +1,126 @@
+package excel_exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFlatFileBackendDelimited(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{"csv", FormatCSV, "a,1\nb,2\n"},
+		{"tsv", FormatTSV, "a\t1\nb\t2\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newFlatFileBackend(tt.format)
+			if err := b.newSheet("Sheet1"); err != nil {
+				t.Fatal(err)
+			}
+
+			rows := []Row{
+				{Cells: []Cell{{Value: "a"}, {Value: 1}}},
+				{Cells: []Cell{{Value: "b"}, {Value: 2}}},
+			}
+			for i, row := range rows {
+				if err := b.writeRow(i+1, row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := b.finalize(); err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			if err := b.save(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Fatalf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestFlatFileBackendJSONL(t *testing.T) {
+	b := newFlatFileBackend(FormatJSONL)
+	if err := b.newSheet("Sheet1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.writeRow(1, Row{Cells: []Cell{{Value: "a"}, {Value: 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.finalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := b.save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "[\"a\",1]\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestFlatFileBackendFormulaOverridesValue(t *testing.T) {
+	b := newFlatFileBackend(FormatCSV)
+	if err := b.newSheet("Sheet1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.writeRow(1, Row{Cells: []Cell{{Value: 3, Formula: "=1+2"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.finalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := b.save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != "=1+2" {
+		t.Fatalf("got %q", buf.String())
+	}
+}
+
+func TestFlatFileBackendMultiSheetZipsOutput(t *testing.T) {
+	b := newFlatFileBackend(FormatCSV)
+	for _, name := range []string{"Sheet1", "Sheet2"} {
+		if err := b.newSheet(name); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.writeRow(1, Row{Cells: []Cell{{Value: name}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.finalize(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := b.save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := map[string]bool{}
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	if len(names) != 2 || !names["Sheet1.csv"] || !names["Sheet2.csv"] {
+		t.Fatalf("unexpected zip entries: %v", names)
+	}
+}
@@ -1,7 +1,10 @@
 package excel_exporter
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"sync"
 
 	"github.com/xuri/excelize/v2"
@@ -22,6 +25,7 @@ type SheetData struct {
 	Name     string
 	RowFunc  RowDataFunc
 	InitFunc InitFunc
+	Layout   SheetLayout
 }
 
 // Exporter provides methods for exporting data to Excel files.
@@ -31,148 +35,102 @@ type Exporter struct {
 	CurrentSheet    string // Current sheet name
 	UseStreamWriter bool
 	StreamWriter    *excelize.StreamWriter
+	Format          Format
+
+	// ProgressFunc, if set, is invoked every ProgressEvery rows written to a
+	// sheet with the number of rows written so far and an approximation of
+	// how many bytes are currently buffered.
+	ProgressFunc func(sheet string, rowsWritten int64, bytesBuffered int64)
+	// ProgressEvery is the number of rows between ProgressFunc invocations.
+	// It defaults to 1000 when left unset.
+	ProgressEvery int
 }
 
-// New creates a new Exporter instance.
-func New(fileName string, useStreamWriter bool) *Exporter {
-	return &Exporter{
+// New creates a new Exporter instance. The output Format defaults to the one
+// inferred from fileName's extension; pass WithFormat to override it.
+func New(fileName string, useStreamWriter bool, opts ...ExportOption) *Exporter {
+	e := &Exporter{
 		File:            excelize.NewFile(),
 		FileName:        fileName,
 		UseStreamWriter: useStreamWriter,
+		Format:          formatFromExtension(fileName),
 	}
-}
-
-// Export exports the Excel file.
-func (e *Exporter) Export(sheets []SheetData) error {
-	// call close to remove temp files
-	defer e.File.Close()
-
-	for i, sheet := range sheets {
-		if _, err := e.File.NewSheet(sheet.Name); err != nil {
-			return fmt.Errorf("failed to create a new sheet: %w", err)
-		}
-
-		// delete default sheet
-		if i == 0 && e.File.SheetCount > 1 {
-			if err := e.File.DeleteSheet("Sheet1"); err != nil {
-				return fmt.Errorf("failed to delete default sheet: %w", err)
-			}
-		}
 
-		if e.UseStreamWriter {
-			if err := e.exportUsingStreamWriter(sheet); err != nil {
-				return err
-			}
-		} else {
-			if err := e.exportUsingMemory(sheet); err != nil {
-				return err
-			}
-		}
+	for _, opt := range opts {
+		opt(e)
 	}
 
-	return e.File.SaveAs(e.FileName)
+	return e
 }
 
-func (e *Exporter) exportUsingStreamWriter(sheet SheetData) error {
-	initFunc := func(sheetName string) error {
-		var err error
-		// cell merge and style will be lost if no flush
-		if e.StreamWriter != nil {
-			err = e.StreamWriter.Flush()
-			if err != nil {
-				return err
-			}
-		}
-		e.StreamWriter, err = e.File.NewStreamWriter(sheetName)
-		if err != nil {
-			return err
-		}
-		if sheet.InitFunc != nil {
-			err = sheet.InitFunc(e)
-			if err != nil {
-				return err
-			}
-		}
-		return err
-	}
-
-	writeRowFunc := func(sheetName string, rowID int, row Row) error {
-		rowCells := make([]interface{}, len(row.Cells))
-		for j, cell := range row.Cells {
-			rowCells[j] = cell
-		}
-
-		cell, _ := excelize.CoordinatesToCellName(1, rowID)
-		if err := e.StreamWriter.SetRow(cell, rowCells, row.RowOpts...); err != nil {
-			return err
-		}
+// Export writes sheets to e.FileName in e.Format.
+func (e *Exporter) Export(sheets []SheetData) error {
+	return e.ExportContext(context.Background(), sheets)
+}
 
-		for _, mergeCell := range row.MergeCells {
-			if err := e.StreamWriter.MergeCell(mergeCell.TopLeftCell, mergeCell.BottomRightCell); err != nil {
-				return err
-			}
-		}
+// ExportTo writes sheets to w in e.Format without touching disk, e.g. to
+// serve a report directly from an http.Handler.
+func (e *Exporter) ExportTo(w io.Writer, sheets []SheetData) error {
+	return e.exportContextTo(context.Background(), w, sheets)
+}
 
-		return nil
+// ExportContext writes sheets to e.FileName, aborting as soon as ctx is
+// canceled or its deadline expires. Long-running exports of millions of rows
+// can then be stopped mid-stream, e.g. when an HTTP client disconnects.
+//
+// If any SheetData.RowFunc was built with UseRowChan, pass that same ctx to
+// UseRowChan. exportHelper and the UseRowChan producer each only watch the
+// ctx they were given, so cancellation only propagates end-to-end when both
+// sides share one context.
+func (e *Exporter) ExportContext(ctx context.Context, sheets []SheetData) error {
+	if e.FileName == "" {
+		return fmt.Errorf("excel_exporter: FileName is required for Export; use ExportTo to write to an io.Writer instead")
 	}
 
-	if err := e.exportHelper(sheet, initFunc, writeRowFunc); err != nil {
-		return err
+	f, err := os.Create(e.FileName)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
 	}
+	defer f.Close()
 
-	return e.StreamWriter.Flush()
+	return e.exportContextTo(ctx, f, sheets)
 }
 
-func (e *Exporter) exportUsingMemory(sheet SheetData) error {
-	initFunc := func(sheetName string) error {
-		if sheet.InitFunc != nil {
-			return sheet.InitFunc(e)
-		}
-		return nil
-	}
-
-	writeRowFunc := func(sheetName string, rowID int, row Row) error {
-		for j, cell := range row.Cells {
-			cellName, _ := excelize.CoordinatesToCellName(j+1, rowID)
-			if err := e.File.SetCellValue(sheetName, cellName, cell.Value); err != nil {
-				return err
-			}
+func (e *Exporter) exportContextTo(ctx context.Context, w io.Writer, sheets []SheetData) error {
+	// call close to remove temp files
+	defer e.File.Close()
 
-			if cell.StyleID > 0 {
-				if err := e.File.SetCellStyle(sheetName, cellName, cellName, cell.StyleID); err != nil {
-					return err
-				}
-			}
+	b := e.newBackend()
 
-			if cell.Formula != "" {
-				if err := e.File.SetCellFormula(sheetName, cellName, cell.Formula); err != nil {
-					return err
-				}
-			}
+	for _, sheet := range sheets {
+		if err := e.exportHelper(ctx, b, sheet); err != nil {
+			return err
 		}
 
-		for _, mergeCell := range row.MergeCells {
-			if err := e.File.MergeCell(sheetName, mergeCell.TopLeftCell, mergeCell.BottomRightCell); err != nil {
-				return err
-			}
+		if err := b.finalize(); err != nil {
+			return err
 		}
-
-		return nil
 	}
 
-	return e.exportHelper(sheet, initFunc, writeRowFunc)
+	return b.save(w)
 }
 
-func (e *Exporter) exportHelper(sheet SheetData, initFunc func(string) error, writeRowFunc func(string, int, Row) error) error {
-	rowID := 1
+func (e *Exporter) exportHelper(ctx context.Context, b backend, sheet SheetData) error {
 	sheetSuffix := 0
 	e.CurrentSheet = sheet.Name
 
-	if err := initFunc(e.CurrentSheet); err != nil {
+	if err := e.startSheet(b, sheet, e.CurrentSheet); err != nil {
 		return err
 	}
+	rowID := firstDataRowID(sheet.Layout)
+
 	var rowIndex = 0
+	var rowsWritten int64
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		row, err := sheet.RowFunc(rowIndex)
 		if err != nil {
 			return err
@@ -183,34 +141,99 @@ func (e *Exporter) exportHelper(sheet SheetData, initFunc func(string) error, wr
 		}
 
 		if rowID > SheetMaxRows {
-			sheetSuffix++
-			rowID = 1
-
-			// Create a new sheet if row count exceeds SheetMaxRows
-			currentSheetName := fmt.Sprintf("%s_%d", sheet.Name, sheetSuffix)
-			if _, err := e.File.NewSheet(currentSheetName); err != nil {
-				return fmt.Errorf("failed to create a new sheet: %w", err)
+			if err := b.finalizeLayout(sheet.Layout); err != nil {
+				return err
 			}
 
-			e.CurrentSheet = currentSheetName
-			if err := initFunc(e.CurrentSheet); err != nil {
+			sheetSuffix++
+
+			// Start a new sheet if row count exceeds SheetMaxRows
+			e.CurrentSheet = fmt.Sprintf("%s_%d", sheet.Name, sheetSuffix)
+			if err := e.startSheet(b, sheet, e.CurrentSheet); err != nil {
 				return err
 			}
+			rowID = firstDataRowID(sheet.Layout)
 		}
 
-		if err := writeRowFunc(e.CurrentSheet, rowID, row); err != nil {
+		if err := b.writeRow(rowID, row); err != nil {
 			return err
 		}
 
 		rowID++
 		rowIndex++
+		rowsWritten++
+
+		e.reportProgress(b, rowsWritten)
+	}
+
+	return b.finalizeLayout(sheet.Layout)
+}
+
+// defaultProgressEvery is the row interval used when ProgressEvery is left
+// unset (or set to a non-positive value).
+const defaultProgressEvery = 1000
+
+// reportProgress invokes e.ProgressFunc every e.ProgressEvery rows, if set.
+func (e *Exporter) reportProgress(b backend, rowsWritten int64) {
+	if e.ProgressFunc == nil {
+		return
+	}
+
+	every := e.ProgressEvery
+	if every <= 0 {
+		every = defaultProgressEvery
+	}
+
+	if rowsWritten%int64(every) != 0 {
+		return
+	}
+	e.ProgressFunc(e.CurrentSheet, rowsWritten, b.bufferedBytes())
+}
+
+// startSheet opens sheetName on b, applies the sheet's layout, runs its
+// InitFunc and, if set, writes the layout's header as row 1.
+func (e *Exporter) startSheet(b backend, sheet SheetData, sheetName string) error {
+	if err := b.newSheet(sheetName); err != nil {
+		return err
+	}
+
+	if err := b.applyLayout(sheet.Layout); err != nil {
+		return err
+	}
+
+	if sheet.InitFunc != nil {
+		if err := sheet.InitFunc(e); err != nil {
+			return err
+		}
+	}
+
+	if sheet.Layout.Header != nil {
+		if err := b.writeRow(1, Row{Cells: sheet.Layout.Header}); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// UseRowChan returns a RowDataFunc that will use a channel to send Row objects to the given function.
-func UseRowChan(sendDataFunc func(dataCh chan Row) error) RowDataFunc {
+// firstDataRowID returns the first row number available for data, accounting
+// for a header row occupying row 1.
+func firstDataRowID(layout SheetLayout) int {
+	if layout.Header != nil {
+		return 2
+	}
+	return 1
+}
+
+// UseRowChan returns a RowDataFunc that will use a channel to send Row
+// objects produced by sendDataFunc on a separate goroutine. sendDataFunc
+// receives ctx so that producers doing e.g. DB queries can watch for
+// cancellation and stop sending mid-stream; once ctx is done, further reads
+// from the resulting RowDataFunc return ctx.Err().
+//
+// ctx is independent of the context passed to Exporter.ExportContext: pass
+// the same one to both, or cancellation on one side won't stop the other.
+func UseRowChan(ctx context.Context, sendDataFunc func(ctx context.Context, dataCh chan Row) error) RowDataFunc {
 	var once sync.Once
 	var dataCh chan Row
 	var sendErr error
@@ -220,18 +243,25 @@ func UseRowChan(sendDataFunc func(dataCh chan Row) error) RowDataFunc {
 			dataCh = make(chan Row)
 			go func() {
 				defer close(dataCh)
-				sendErr = sendDataFunc(dataCh)
+				sendErr = sendDataFunc(ctx, dataCh)
 			}()
 		})
 
-		row, ok := <-dataCh
-		if sendErr != nil {
-			return Row{}, sendErr
+		if err := ctx.Err(); err != nil {
+			return Row{}, err
 		}
 
-		if !ok {
-			return Row{}, nil
+		select {
+		case <-ctx.Done():
+			return Row{}, ctx.Err()
+		case row, ok := <-dataCh:
+			if sendErr != nil {
+				return Row{}, sendErr
+			}
+			if !ok {
+				return Row{}, nil
+			}
+			return row, nil
 		}
-		return row, nil
 	}
 }
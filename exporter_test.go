@@ -0,0 +1,104 @@
+package excel_exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestExportToWritesWithoutFileName(t *testing.T) {
+	exp := New("", false)
+	var buf bytes.Buffer
+	err := exp.ExportTo(&buf, []SheetData{{Name: "Sheet1", RowFunc: rowFuncN(2)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := f.GetCellValue("Sheet1", "A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "1" {
+		t.Fatalf("expected A1 to be %q, got %q", "1", got)
+	}
+}
+
+func TestExportRequiresFileName(t *testing.T) {
+	exp := New("", false)
+	err := exp.Export([]SheetData{{Name: "Sheet1", RowFunc: rowFuncN(1)}})
+	if err == nil {
+		t.Fatal("expected an error when FileName is empty, got nil")
+	}
+	if !strings.Contains(err.Error(), "ExportTo") {
+		t.Fatalf("expected error to point callers at ExportTo, got: %v", err)
+	}
+}
+
+func TestExportWritesToFile(t *testing.T) {
+	path := t.TempDir() + "/out.xlsx"
+	exp := New(path, false)
+	if err := exp.Export([]SheetData{{Name: "Sheet1", RowFunc: rowFuncN(2)}}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := f.GetCellValue("Sheet1", "A2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "2" {
+		t.Fatalf("expected A2 to be %q, got %q", "2", got)
+	}
+}
+
+func TestExportContextStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	exp := New("", false)
+	var buf bytes.Buffer
+	err := exp.exportContextTo(ctx, &buf, []SheetData{{
+		Name: "Sheet1",
+		RowFunc: func(rowNumber int) (Row, error) {
+			if rowNumber == 3 {
+				cancel()
+			}
+			return Row{Cells: []Cell{{Value: rowNumber}}}, nil
+		},
+	}})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestProgressFuncUsesDefaultIntervalWhenProgressEveryUnset(t *testing.T) {
+	exp := New("", false)
+	var calls []int64
+	exp.ProgressFunc = func(sheet string, rowsWritten, bytesBuffered int64) {
+		calls = append(calls, rowsWritten)
+	}
+
+	var buf bytes.Buffer
+	err := exp.ExportTo(&buf, []SheetData{{Name: "Sheet1", RowFunc: rowFuncN(2500)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != 2 || calls[0] != 1000 || calls[1] != 2000 {
+		t.Fatalf("expected ProgressFunc calls at [1000 2000] using the default interval, got %v", calls)
+	}
+}
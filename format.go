@@ -0,0 +1,55 @@
+package excel_exporter
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Format selects the output file format produced by Export.
+type Format int
+
+const (
+	// FormatXLSX writes a standard Excel workbook via excelize. This is the default.
+	FormatXLSX Format = iota
+	// FormatCSV writes each sheet as comma-separated values.
+	FormatCSV
+	// FormatTSV writes each sheet as tab-separated values.
+	FormatTSV
+	// FormatJSONL writes each sheet as newline-delimited JSON arrays, one per row.
+	FormatJSONL
+)
+
+// ExportOption configures optional Exporter behavior.
+type ExportOption func(*Exporter)
+
+// WithFormat overrides the output format that would otherwise be inferred
+// from FileName's extension.
+func WithFormat(format Format) ExportOption {
+	return func(e *Exporter) {
+		e.Format = format
+	}
+}
+
+// WithProgress sets fn to be invoked every n rows written to a sheet. See
+// Exporter.ProgressEvery for the default applied when n is left unset.
+func WithProgress(n int, fn func(sheet string, rowsWritten int64, bytesBuffered int64)) ExportOption {
+	return func(e *Exporter) {
+		e.ProgressEvery = n
+		e.ProgressFunc = fn
+	}
+}
+
+// formatFromExtension infers a Format from fileName's extension, defaulting
+// to FormatXLSX when the extension is unrecognized.
+func formatFromExtension(fileName string) Format {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return FormatCSV
+	case ".tsv":
+		return FormatTSV
+	case ".jsonl":
+		return FormatJSONL
+	default:
+		return FormatXLSX
+	}
+}
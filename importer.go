@@ -0,0 +1,208 @@
+package excel_exporter
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// RowHandlerFunc is a function type invoked for each row read from a sheet.
+// The rowNumber parameter indicates the current Excel row number (starting from 1).
+// After the last row, Import calls it once more with an empty Row (nil Cells)
+// and rowNumber set to the total number of rows read across the matched sheets.
+type RowHandlerFunc func(rowNumber int, row Row) error
+
+// ImporterInitFunc is a function type that will be called at the beginning of each sheet.
+type ImporterInitFunc func(importer *Importer) error
+
+// SheetHandler describes how a single sheet (or all sheets, when Name is "")
+// should be read.
+type SheetHandler struct {
+	Name           string // sheet name, or "" to import every sheet in the file
+	InitFunc       ImporterInitFunc
+	RowHandlerFunc RowHandlerFunc
+}
+
+// ImporterOption configures optional excelize behavior for an Importer.
+type ImporterOption func(*Importer)
+
+// WithUnzipSizeLimit overrides excelize's default uncompressed size limits for
+// the underlying zip archive and its XML entries, guarding against zip-bomb
+// XLSX input.
+func WithUnzipSizeLimit(unzipSizeLimit, unzipXMLSizeLimit int64) ImporterOption {
+	return func(im *Importer) {
+		im.unzipSizeLimit = unzipSizeLimit
+		im.unzipXMLSizeLimit = unzipXMLSizeLimit
+	}
+}
+
+// Importer provides methods for importing data from Excel files.
+type Importer struct {
+	File            *excelize.File
+	FileName        string
+	CurrentSheet    string // Current sheet name
+	UseStreamReader bool
+
+	unzipSizeLimit    int64
+	unzipXMLSizeLimit int64
+}
+
+// NewImporter creates a new Importer instance.
+func NewImporter(fileName string, useStreamReader bool, opts ...ImporterOption) *Importer {
+	im := &Importer{
+		FileName:        fileName,
+		UseStreamReader: useStreamReader,
+	}
+
+	for _, opt := range opts {
+		opt(im)
+	}
+
+	return im
+}
+
+// Import opens the underlying XLSX file and feeds each sheet it describes to
+// the matching RowHandlerFunc, row by row.
+func (im *Importer) Import(sheets []SheetHandler) error {
+	options := excelize.Options{
+		UnzipSizeLimit:    im.unzipSizeLimit,
+		UnzipXMLSizeLimit: im.unzipXMLSizeLimit,
+	}
+
+	file, err := excelize.OpenFile(im.FileName, options)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	im.File = file
+	defer im.File.Close()
+
+	for _, sheet := range sheets {
+		sheetNames := []string{sheet.Name}
+		if sheet.Name == "" {
+			sheetNames = im.File.GetSheetList()
+		}
+
+		// totalRows accumulates the row count across every matched sheet, for
+		// the closing sentinel call below. Each sheet's own rows are still
+		// numbered from 1 so RowHandlerFunc always sees the sheet's own
+		// Excel row number.
+		var totalRows int
+		for _, sheetName := range sheetNames {
+			im.CurrentSheet = sheetName
+
+			if sheet.InitFunc != nil {
+				if err := sheet.InitFunc(im); err != nil {
+					return err
+				}
+			}
+
+			sheetRows, err := im.importSheet(sheetName, sheet.RowHandlerFunc)
+			if err != nil {
+				return err
+			}
+			totalRows += sheetRows
+		}
+
+		if sheet.RowHandlerFunc != nil {
+			if err := sheet.RowHandlerFunc(totalRows, Row{}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// importSheet reads every row of sheetName, numbering rows from 1, and
+// returns how many rows it processed.
+func (im *Importer) importSheet(sheetName string, handle RowHandlerFunc) (int, error) {
+	rows, err := im.File.Rows(sheetName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read sheet %q: %w", sheetName, err)
+	}
+	defer rows.Close()
+
+	rowIndex := 0
+	for rows.Next() {
+		columns, err := rows.Columns()
+		if err != nil {
+			return rowIndex, fmt.Errorf("failed to read row %d of sheet %q: %w", rowIndex+1, sheetName, err)
+		}
+
+		rowIndex++
+
+		row, err := im.buildRow(sheetName, rowIndex, columns)
+		if err != nil {
+			return rowIndex, err
+		}
+
+		if handle != nil {
+			if err := handle(rowIndex, row); err != nil {
+				return rowIndex, err
+			}
+		}
+	}
+
+	return rowIndex, rows.Error()
+}
+
+func (im *Importer) buildRow(sheetName string, rowIndex int, columns []string) (Row, error) {
+	cells := make([]Cell, len(columns))
+	for j, value := range columns {
+		cells[j] = Cell{Value: value}
+
+		if !im.UseStreamReader {
+			cellName, err := excelize.CoordinatesToCellName(j+1, rowIndex)
+			if err != nil {
+				return Row{}, err
+			}
+
+			styleID, err := im.File.GetCellStyle(sheetName, cellName)
+			if err != nil {
+				return Row{}, err
+			}
+			cells[j].StyleID = styleID
+		}
+	}
+
+	return Row{Cells: cells}, nil
+}
+
+// ReceiveRowChan returns a RowHandlerFunc that forwards each imported row onto
+// a channel, driving a goroutine-based consumer pipeline. It is the
+// UseRowChan-symmetric counterpart for import.
+//
+// If recvFunc returns before the sheet finishes (e.g. on a validation error),
+// done is closed; the select below then lets the handler return recvErr
+// instead of blocking forever on a send nobody will receive.
+func ReceiveRowChan(recvFunc func(dataCh chan Row) error) RowHandlerFunc {
+	var once sync.Once
+	var dataCh chan Row
+	var done chan struct{}
+	var recvErr error
+
+	return func(rowNumber int, row Row) error {
+		once.Do(func() {
+			dataCh = make(chan Row)
+			done = make(chan struct{})
+			go func() {
+				defer close(done)
+				recvErr = recvFunc(dataCh)
+			}()
+		})
+
+		if row.Cells == nil {
+			close(dataCh)
+			<-done
+			return recvErr
+		}
+
+		select {
+		case dataCh <- row:
+			return nil
+		case <-done:
+			return recvErr
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package excel_exporter
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTestWorkbook(t *testing.T, sheets map[string]int) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "import_test.xlsx")
+	exp := New(path, false)
+
+	var data []SheetData
+	for _, name := range []string{"Sheet1", "Sheet2"} {
+		rowCount, ok := sheets[name]
+		if !ok {
+			continue
+		}
+		data = append(data, SheetData{
+			Name: name,
+			RowFunc: func(rowNumber int) (Row, error) {
+				if rowNumber >= rowCount {
+					return Row{}, nil
+				}
+				return Row{Cells: []Cell{{Value: rowNumber + 1}}}, nil
+			},
+		})
+	}
+
+	if err := exp.Export(data); err != nil {
+		t.Fatalf("failed to set up test workbook: %v", err)
+	}
+	return path
+}
+
+func TestImportSingleSheet(t *testing.T) {
+	path := writeTestWorkbook(t, map[string]int{"Sheet1": 3})
+
+	imp := NewImporter(path, false)
+	var got []int
+	err := imp.Import([]SheetHandler{{
+		Name: "Sheet1",
+		RowHandlerFunc: func(rowNumber int, row Row) error {
+			if row.Cells == nil {
+				return nil
+			}
+			got = append(got, rowNumber)
+			return nil
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("unexpected row numbers: %v", got)
+	}
+}
+
+func TestImportAllSheetsResetsRowNumberPerSheetAndAccumulatesSentinel(t *testing.T) {
+	path := writeTestWorkbook(t, map[string]int{"Sheet1": 2, "Sheet2": 3})
+
+	imp := NewImporter(path, false)
+	var rowNumbersBySheet = map[string][]int{}
+	var finalCount int
+	err := imp.Import([]SheetHandler{{
+		Name: "",
+		RowHandlerFunc: func(rowNumber int, row Row) error {
+			if row.Cells == nil {
+				finalCount = rowNumber
+				return nil
+			}
+			rowNumbersBySheet[imp.CurrentSheet] = append(rowNumbersBySheet[imp.CurrentSheet], rowNumber)
+			return nil
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := rowNumbersBySheet["Sheet1"]; len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("Sheet1 row numbers not reset: %v", got)
+	}
+	if got := rowNumbersBySheet["Sheet2"]; len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("Sheet2 row numbers not reset: %v", got)
+	}
+	if finalCount != 5 {
+		t.Fatalf("expected cumulative sentinel count 5, got %d", finalCount)
+	}
+}
+
+func TestReceiveRowChan(t *testing.T) {
+	path := writeTestWorkbook(t, map[string]int{"Sheet1": 3})
+
+	imp := NewImporter(path, false)
+	var got []any
+	handler := ReceiveRowChan(func(dataCh chan Row) error {
+		for row := range dataCh {
+			got = append(got, row.Cells[0].Value)
+		}
+		return nil
+	})
+
+	err := imp.Import([]SheetHandler{{Name: "Sheet1", RowHandlerFunc: handler}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 rows, got %v", got)
+	}
+}
+
+func TestReceiveRowChanEarlyExitDoesNotDeadlock(t *testing.T) {
+	path := writeTestWorkbook(t, map[string]int{"Sheet1": 5})
+
+	imp := NewImporter(path, false)
+	wantErr := errors.New("early exit after 2 rows")
+	handler := ReceiveRowChan(func(dataCh chan Row) error {
+		count := 0
+		for range dataCh {
+			count++
+			if count == 2 {
+				return wantErr
+			}
+		}
+		return nil
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		done <- imp.Import([]SheetHandler{{Name: "Sheet1", RowHandlerFunc: handler}})
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("expected %v, got %v", wantErr, err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Import did not return within 3s; ReceiveRowChan deadlocked")
+	}
+}
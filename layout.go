@@ -0,0 +1,67 @@
+package excel_exporter
+
+import (
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Panes configures frozen panes for a sheet: Rows and Cols give the number of
+// leading rows/columns that stay fixed while the rest of the sheet scrolls.
+type Panes struct {
+	Rows int
+	Cols int
+}
+
+func (p Panes) toExcelize() (*excelize.Panes, error) {
+	topLeftCol, err := excelize.ColumnNumberToName(p.Cols + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	activePane := "topLeft"
+	switch {
+	case p.Rows > 0 && p.Cols > 0:
+		activePane = "bottomRight"
+	case p.Rows > 0:
+		activePane = "bottomLeft"
+	case p.Cols > 0:
+		activePane = "topRight"
+	}
+
+	return &excelize.Panes{
+		Freeze:      true,
+		XSplit:      p.Cols,
+		YSplit:      p.Rows,
+		TopLeftCell: fmt.Sprintf("%s%d", topLeftCol, p.Rows+1),
+		ActivePane:  activePane,
+	}, nil
+}
+
+// TableRange describes an Excel table to add to a sheet.
+type TableRange struct {
+	Range     string
+	Name      string
+	StyleName string
+}
+
+func (t TableRange) toExcelize() *excelize.Table {
+	return &excelize.Table{Range: t.Range, Name: t.Name, StyleName: t.StyleName}
+}
+
+// SheetLayout configures sheet-level presentation that would otherwise
+// require raw excelize calls inside InitFunc: column widths, a header row
+// (written as row 1 and re-emitted on every overflow sheet), frozen panes, an
+// auto-filter, and table ranges. It is applied the same way whether Exporter
+// is using the memory or stream writer path.
+type SheetLayout struct {
+	ColumnWidths map[string]float64 // column letter, e.g. "A", to width
+	Header       []Cell
+	FreezePanes  *Panes
+	AutoFilter   string // range reference, e.g. "A1:D1"
+	// Tables lists the tables to add to the sheet. Exporter.UseStreamWriter
+	// sheets are limited to a single table, matching excelize.StreamWriter's
+	// own one-table-per-sheet restriction; Export returns an error if more
+	// than one is given in that mode.
+	Tables []TableRange
+}
@@ -0,0 +1,172 @@
+package excel_exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func rowFuncN(n int) RowDataFunc {
+	return func(rowNumber int) (Row, error) {
+		if rowNumber >= n {
+			return Row{}, nil
+		}
+		return Row{Cells: []Cell{{Value: rowNumber + 1}}}, nil
+	}
+}
+
+func testLayout() SheetLayout {
+	return SheetLayout{
+		ColumnWidths: map[string]float64{"A": 20},
+		Header:       []Cell{{Value: "id"}},
+		FreezePanes:  &Panes{Rows: 1},
+		AutoFilter:   "A1:A1",
+		Tables:       []TableRange{{Range: "A1:A4", Name: "t1"}},
+	}
+}
+
+func testLayoutAppliedSheet(t *testing.T, useStreamWriter bool) {
+	t.Helper()
+
+	exp := New("layout_test.xlsx", useStreamWriter)
+	var buf bytes.Buffer
+	err := exp.ExportTo(&buf, []SheetData{{
+		Name:    "Sheet1",
+		RowFunc: rowFuncN(3),
+		Layout:  testLayout(),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	header, err := f.GetCellValue("Sheet1", "A1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header != "id" {
+		t.Fatalf("expected header row 1 to be %q, got %q", "id", header)
+	}
+
+	data, err := f.GetCellValue("Sheet1", "A2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data != "1" {
+		t.Fatalf("expected first data row at A2 to be %q, got %q", "1", data)
+	}
+
+	width, err := f.GetColWidth("Sheet1", "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if width != 20 {
+		t.Fatalf("expected column A width 20, got %v", width)
+	}
+
+	panes, err := f.GetPanes("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !panes.Freeze || panes.YSplit != 1 {
+		t.Fatalf("expected frozen pane at row 1, got %+v", panes)
+	}
+
+	tables, err := f.GetTables("Sheet1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || tables[0].Name != "t1" {
+		t.Fatalf("expected a single table named t1, got %+v", tables)
+	}
+}
+
+func TestSheetLayoutMemoryMode(t *testing.T) {
+	testLayoutAppliedSheet(t, false)
+}
+
+func TestSheetLayoutStreamMode(t *testing.T) {
+	testLayoutAppliedSheet(t, true)
+}
+
+func TestSheetLayoutStreamModeRejectsMultipleTables(t *testing.T) {
+	exp := New("layout_test.xlsx", true)
+	var buf bytes.Buffer
+	err := exp.ExportTo(&buf, []SheetData{{
+		Name:    "Sheet1",
+		RowFunc: rowFuncN(3),
+		Layout: SheetLayout{
+			Tables: []TableRange{
+				{Range: "A1:A3", Name: "t1"},
+				{Range: "A1:A3", Name: "t2"},
+			},
+		},
+	}})
+	if err == nil {
+		t.Fatal("expected an error for multiple tables in stream mode, got nil")
+	}
+	if !strings.Contains(err.Error(), "only one table") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestFlatFileBackendReemitsHeaderOnOverflow drives the CSV backend past
+// SheetMaxRows, confirming the header configured via SheetLayout.Header is
+// re-written at the top of the overflow sheet, not just the first one. Two
+// sheets come out of one SheetData, so the output is the zipped form.
+func TestFlatFileBackendReemitsHeaderOnOverflow(t *testing.T) {
+	exp := New("layout_test.csv", false)
+	var buf bytes.Buffer
+	err := exp.ExportTo(&buf, []SheetData{{
+		Name:    "Sheet1",
+		RowFunc: rowFuncN(SheetMaxRows),
+		Layout:  SheetLayout{Header: []Cell{{Value: "id"}}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		raw, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content[f.Name] = string(raw)
+	}
+
+	firstLines := strings.Split(strings.TrimRight(content["Sheet1.csv"], "\n"), "\n")
+	if firstLines[0] != "id" {
+		t.Fatalf("expected Sheet1's header on line 1, got %q", firstLines[0])
+	}
+	if len(firstLines) != SheetMaxRows {
+		t.Fatalf("expected Sheet1 to hold exactly SheetMaxRows lines (header + data), got %d", len(firstLines))
+	}
+
+	overflowLines := strings.Split(strings.TrimRight(content["Sheet1_1.csv"], "\n"), "\n")
+	if overflowLines[0] != "id" {
+		t.Fatalf("expected overflow sheet's header re-emitted on its own line 1, got %q", overflowLines[0])
+	}
+	if len(overflowLines) != 2 {
+		t.Fatalf("expected overflow sheet to hold header + 1 remaining data row, got %d lines: %v", len(overflowLines), overflowLines)
+	}
+}
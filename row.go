@@ -0,0 +1,21 @@
+package excel_exporter
+
+import "github.com/xuri/excelize/v2"
+
+// Cell is an alias for excelize.Cell, letting callers set a per-cell value,
+// style and formula without importing excelize directly.
+type Cell = excelize.Cell
+
+// MergeCell describes a rectangular range of cells to merge.
+type MergeCell struct {
+	TopLeftCell     string
+	BottomRightCell string
+}
+
+// Row represents a single row of data read from, or written to, a sheet.
+// A Row with a nil Cells slice signals the end of a data source.
+type Row struct {
+	Cells      []Cell
+	MergeCells []MergeCell
+	RowOpts    []excelize.RowOpts
+}
@@ -0,0 +1,81 @@
+package excel_exporter
+
+import (
+	"database/sql"
+	"sync"
+)
+
+// SQLRowsOption configures UseSQLRows.
+type SQLRowsOption func(*sqlHeaderOptions)
+
+type sqlHeaderOptions struct {
+	emitHeader    bool
+	headerStyleID int
+}
+
+// WithSQLHeader emits a header row, derived from rows.Columns(), styled with
+// styleID, before the first row produced by UseSQLRows.
+func WithSQLHeader(styleID int) SQLRowsOption {
+	return func(o *sqlHeaderOptions) {
+		o.emitHeader = true
+		o.headerStyleID = styleID
+	}
+}
+
+// UseSQLRows returns a RowDataFunc that reads from rows, using mapper to
+// translate each scanned row into a Row. rows.Columns() is read once; a
+// scratch []any sized to the column count is then reused on every call to
+// rows.Scan. Once rows.Next() is false, it returns an empty Row so
+// exportHelper terminates cleanly.
+func UseSQLRows(rows *sql.Rows, mapper func(cols []string, vals []any) (Row, error), opts ...SQLRowsOption) RowDataFunc {
+	var o sqlHeaderOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var once sync.Once
+	var cols []string
+	var scratch []any
+	var initErr error
+	var headerSent bool
+
+	return func(rowNumber int) (Row, error) {
+		once.Do(func() {
+			cols, initErr = rows.Columns()
+			if initErr != nil {
+				return
+			}
+			scratch = make([]any, len(cols))
+			for i := range scratch {
+				scratch[i] = new(any)
+			}
+		})
+		if initErr != nil {
+			return Row{}, initErr
+		}
+
+		if o.emitHeader && !headerSent {
+			headerSent = true
+			cells := make([]Cell, len(cols))
+			for i, col := range cols {
+				cells[i] = Cell{Value: col, StyleID: o.headerStyleID}
+			}
+			return Row{Cells: cells}, nil
+		}
+
+		if !rows.Next() {
+			return Row{}, rows.Err()
+		}
+
+		if err := rows.Scan(scratch...); err != nil {
+			return Row{}, err
+		}
+
+		values := make([]any, len(scratch))
+		for i, v := range scratch {
+			values[i] = *(v.(*any))
+		}
+
+		return mapper(cols, values)
+	}
+}
@@ -0,0 +1,129 @@
+package excel_exporter
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+type sqlTestDriver struct{ rows [][]driver.Value }
+
+func (d sqlTestDriver) Open(name string) (driver.Conn, error) {
+	return sqlTestConn{d.rows}, nil
+}
+
+type sqlTestConn struct{ rows [][]driver.Value }
+
+func (c sqlTestConn) Prepare(query string) (driver.Stmt, error) { return sqlTestStmt{c.rows}, nil }
+func (c sqlTestConn) Close() error                              { return nil }
+func (c sqlTestConn) Begin() (driver.Tx, error)                 { return nil, errors.New("not supported") }
+
+type sqlTestStmt struct{ rows [][]driver.Value }
+
+func (s sqlTestStmt) Close() error  { return nil }
+func (s sqlTestStmt) NumInput() int { return -1 }
+func (s sqlTestStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s sqlTestStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &sqlTestRows{rows: s.rows}, nil
+}
+
+type sqlTestRows struct {
+	rows [][]driver.Value
+	i    int
+}
+
+func (r *sqlTestRows) Columns() []string { return []string{"name", "id"} }
+func (r *sqlTestRows) Close() error      { return nil }
+func (r *sqlTestRows) Next(dest []driver.Value) error {
+	if r.i >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.i])
+	r.i++
+	return nil
+}
+
+func openTestRows(t *testing.T, driverName string, rows [][]driver.Value) *sql.Rows {
+	t.Helper()
+
+	sql.Register(driverName, sqlTestDriver{rows: rows})
+	db, err := sql.Open(driverName, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	result, err := db.Query("select name, id from t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestUseSQLRows(t *testing.T) {
+	rows := openTestRows(t, "usesqlrows-basic", [][]driver.Value{
+		{"alice", int64(1)},
+		{"bob", int64(2)},
+	})
+
+	rf := UseSQLRows(rows, func(cols []string, vals []any) (Row, error) {
+		return Row{Cells: []Cell{{Value: vals[0]}, {Value: vals[1]}}}, nil
+	})
+
+	var got []Row
+	for i := 1; ; i++ {
+		row, err := rf(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if row.Cells == nil {
+			break
+		}
+		got = append(got, row)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(got))
+	}
+	if got[0].Cells[0].Value != "alice" || got[1].Cells[0].Value != "bob" {
+		t.Fatalf("unexpected rows: %+v", got)
+	}
+}
+
+func TestUseSQLRowsWithHeader(t *testing.T) {
+	rows := openTestRows(t, "usesqlrows-header", [][]driver.Value{
+		{"alice", int64(1)},
+	})
+
+	rf := UseSQLRows(rows, func(cols []string, vals []any) (Row, error) {
+		return Row{Cells: []Cell{{Value: vals[0]}, {Value: vals[1]}}}, nil
+	}, WithSQLHeader(5))
+
+	header, err := rf(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header.Cells[0].Value != "name" || header.Cells[0].StyleID != 5 {
+		t.Fatalf("unexpected header row: %+v", header)
+	}
+
+	data, err := rf(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Cells[0].Value != "alice" {
+		t.Fatalf("unexpected data row: %+v", data)
+	}
+
+	end, err := rf(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if end.Cells != nil {
+		t.Fatalf("expected terminal row, got %+v", end)
+	}
+}